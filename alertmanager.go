@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// AlertManager turns raw loss-start/loss-end transitions into notifications,
+// applying a debounce (so a blip shorter than the threshold never pages
+// anyone) and a per-host cooldown (so a flapping host doesn't spam every
+// notifier on every transition).
+type AlertManager struct {
+	notifiers []Notifier
+	debounce  time.Duration
+	cooldown  time.Duration
+
+	mu              sync.Mutex
+	pending         map[string]*time.Timer
+	notifiedEpisode map[string]bool
+	lastNotify      map[string]time.Time
+}
+
+// NewAlertManager returns an AlertManager that fans out to notifiers. A
+// debounce or cooldown of zero disables that behavior.
+func NewAlertManager(notifiers []Notifier, debounce, cooldown time.Duration) *AlertManager {
+	return &AlertManager{
+		notifiers:       notifiers,
+		debounce:        debounce,
+		cooldown:        cooldown,
+		pending:         make(map[string]*time.Timer),
+		notifiedEpisode: make(map[string]bool),
+		lastNotify:      make(map[string]time.Time),
+	}
+}
+
+// LossStart records that host just entered a loss episode. The loss_start
+// notification fires only once the episode has lasted at least the
+// debounce duration.
+func (a *AlertManager) LossStart(host string) {
+	a.mu.Lock()
+	if _, exists := a.pending[host]; exists {
+		a.mu.Unlock()
+		return
+	}
+	if a.debounce <= 0 {
+		a.mu.Unlock()
+		a.markNotifiedAndFire(host, "loss_start", 0, 0)
+		return
+	}
+	a.pending[host] = time.AfterFunc(a.debounce, func() {
+		a.mu.Lock()
+		delete(a.pending, host)
+		a.mu.Unlock()
+		a.markNotifiedAndFire(host, "loss_start", 0, 0)
+	})
+	a.mu.Unlock()
+}
+
+// LossEnd records that host just recovered. If the episode never lasted
+// long enough to clear the debounce, no notification is sent at all.
+func (a *AlertManager) LossEnd(host string, packetsLost int, duration time.Duration) {
+	a.mu.Lock()
+	if timer, exists := a.pending[host]; exists {
+		timer.Stop()
+		delete(a.pending, host)
+		a.mu.Unlock()
+		return
+	}
+	wasNotified := a.notifiedEpisode[host]
+	delete(a.notifiedEpisode, host)
+	a.mu.Unlock()
+
+	if wasNotified {
+		a.fire(host, "loss_end", packetsLost, duration)
+	}
+}
+
+// markNotifiedAndFire sends transition and, only once it actually goes out,
+// records that host has a notified episode so the matching loss_end is
+// guaranteed to be sent too (see fire).
+func (a *AlertManager) markNotifiedAndFire(host, transition string, packetsLost int, duration time.Duration) {
+	if !a.fire(host, transition, packetsLost, duration) {
+		return
+	}
+	a.mu.Lock()
+	a.notifiedEpisode[host] = true
+	a.mu.Unlock()
+}
+
+// fire dispatches transition to every notifier and reports whether it did.
+// The cooldown only ever gates loss_start: a loss_end is only fired at all
+// when its loss_start was actually delivered (see LossEnd), so suppressing
+// it here would tell the operator a host went down but never recovered.
+func (a *AlertManager) fire(host, transition string, packetsLost int, duration time.Duration) bool {
+	a.mu.Lock()
+	if transition != "loss_end" {
+		if last, ok := a.lastNotify[host]; ok && a.cooldown > 0 && time.Since(last) < a.cooldown {
+			a.mu.Unlock()
+			return false
+		}
+	}
+	a.lastNotify[host] = time.Now()
+	a.mu.Unlock()
+
+	ev := NotifyEvent{
+		Host:        host,
+		Transition:  transition,
+		Time:        time.Now(),
+		Duration:    duration,
+		PacketsLost: packetsLost,
+	}
+	for _, n := range a.notifiers {
+		go func(n Notifier) {
+			if err := n.Notify(ev); err != nil {
+				fmt.Fprintf(os.Stderr, "notifier error: %v\n", err)
+			}
+		}(n)
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingNotifier records every NotifyEvent it receives so tests can
+// assert on what actually went out.
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []NotifyEvent
+}
+
+func (r *recordingNotifier) Notify(ev NotifyEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+	return nil
+}
+
+func (r *recordingNotifier) snapshot() []NotifyEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]NotifyEvent, len(r.events))
+	copy(out, r.events)
+	return out
+}
+
+func TestAlertManagerLossStartFiresOnlyAfterDebounce(t *testing.T) {
+	rec := &recordingNotifier{}
+	a := NewAlertManager([]Notifier{rec}, 30*time.Millisecond, 0)
+
+	a.LossStart("h1")
+
+	time.Sleep(10 * time.Millisecond)
+	if got := rec.snapshot(); len(got) != 0 {
+		t.Fatalf("notified before debounce elapsed: %+v", got)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	got := rec.snapshot()
+	if len(got) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(got))
+	}
+	if got[0].Host != "h1" || got[0].Transition != "loss_start" {
+		t.Errorf("notification = %+v, want host=h1 transition=loss_start", got[0])
+	}
+}
+
+func TestAlertManagerLossEndBeforeDebounceNeverNotifies(t *testing.T) {
+	rec := &recordingNotifier{}
+	a := NewAlertManager([]Notifier{rec}, 50*time.Millisecond, 0)
+
+	a.LossStart("h1")
+	a.LossEnd("h1", 2, time.Millisecond)
+
+	// Wait past where the debounce timer would have fired if LossEnd hadn't
+	// canceled it.
+	time.Sleep(80 * time.Millisecond)
+
+	if got := rec.snapshot(); len(got) != 0 {
+		t.Fatalf("got %d notifications for a blip shorter than the debounce, want 0: %+v", len(got), got)
+	}
+}
+
+func TestAlertManagerCooldownSuppressesLossStartButNotLossEnd(t *testing.T) {
+	rec := &recordingNotifier{}
+	a := NewAlertManager([]Notifier{rec}, 0, 50*time.Millisecond)
+
+	a.LossStart("h1")                    // fires immediately: no debounce configured.
+	a.LossEnd("h1", 3, time.Second)      // must fire despite being inside the cooldown window.
+	a.LossStart("h1")                    // second episode, still inside the cooldown: suppressed.
+	a.LossEnd("h1", 1, time.Millisecond) // its loss_start was suppressed, so this must stay silent too.
+
+	time.Sleep(20 * time.Millisecond)
+
+	// fire() dispatches to notifiers in their own goroutines, so successive
+	// calls aren't guaranteed to land in order; count transitions instead.
+	got := rec.snapshot()
+	if len(got) != 2 {
+		t.Fatalf("got %d notifications, want 2 (first loss_start + its loss_end): %+v", len(got), got)
+	}
+	var starts, ends int
+	for _, ev := range got {
+		if ev.Host != "h1" {
+			t.Errorf("notification host = %q, want h1", ev.Host)
+		}
+		switch ev.Transition {
+		case "loss_start":
+			starts++
+		case "loss_end":
+			ends++
+		default:
+			t.Errorf("unexpected transition %q", ev.Transition)
+		}
+	}
+	if starts != 1 {
+		t.Errorf("got %d loss_start notifications, want 1 (second should be cooldown-suppressed)", starts)
+	}
+	if ends != 1 {
+		t.Errorf("got %d loss_end notifications, want 1 (must not be cooldown-suppressed)", ends)
+	}
+}
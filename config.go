@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultHost is used when the user supplies no hosts via flags or config file.
+const defaultHost = "google.com"
+
+// defaultLogFile is the ring log path used when -log-file isn't set.
+const defaultLogFile = "packetlossrecorder.ring"
+
+// Config describes the set of targets to monitor.
+type Config struct {
+	Hosts []string `yaml:"hosts" json:"hosts"`
+}
+
+// LoadConfig reads a YAML or JSON config file based on its extension and
+// returns the hosts it declares.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %q: %w", path, err)
+	}
+
+	cfg := &Config{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON config %q: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML config %q: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config extension for %q, want .yaml, .yml or .json", path)
+	}
+
+	return cfg, nil
+}
+
+// Options holds the fully-resolved command-line configuration for a run.
+type Options struct {
+	Hosts    []string
+	HTTPAddr string
+	LogFile  string
+
+	NotifyWebhook      string
+	NotifySMTPAddr     string
+	NotifySMTPFrom     string
+	NotifySMTPTo       []string
+	NotifySMTPUser     string
+	NotifySMTPPassword string
+	NotifyExec         string
+	NotifyDebounce     time.Duration
+	NotifyCooldown     time.Duration
+
+	ProbeTimeout time.Duration
+	PingInterval time.Duration
+}
+
+// Notifiers builds the Notifier implementations described by the -notify-*
+// flags. It returns an empty slice if none were configured.
+func (o *Options) Notifiers() []Notifier {
+	var notifiers []Notifier
+
+	if o.NotifyWebhook != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(o.NotifyWebhook))
+	}
+
+	if o.NotifySMTPAddr != "" && o.NotifySMTPFrom != "" && len(o.NotifySMTPTo) > 0 {
+		var auth smtp.Auth
+		if o.NotifySMTPUser != "" {
+			host := o.NotifySMTPAddr
+			if i := strings.LastIndex(host, ":"); i != -1 {
+				host = host[:i]
+			}
+			auth = smtp.PlainAuth("", o.NotifySMTPUser, o.NotifySMTPPassword, host)
+		}
+		notifiers = append(notifiers, &SMTPNotifier{
+			Addr: o.NotifySMTPAddr,
+			Auth: auth,
+			From: o.NotifySMTPFrom,
+			To:   o.NotifySMTPTo,
+		})
+	}
+
+	if o.NotifyExec != "" {
+		notifiers = append(notifiers, &ExecNotifier{Command: o.NotifyExec})
+	}
+
+	return notifiers
+}
+
+// ParseFlags determines the list of hosts to monitor from the -hosts and
+// -config flags, falling back to defaultHost when neither is supplied, and
+// reads the optional -http-addr and -log-file flags.
+func ParseFlags() (*Options, error) {
+	hostsFlag := flag.String("hosts", "", "comma-separated list of hosts to ping")
+	configFlag := flag.String("config", "", "path to a YAML or JSON config file listing hosts")
+	httpAddrFlag := flag.String("http-addr", "", "if set, serve RTT history (SVG) and Prometheus metrics on this address (e.g. :8080)")
+	logFileFlag := flag.String("log-file", defaultLogFile, "path to the rotating ring log recording every event")
+
+	notifyWebhookFlag := flag.String("notify-webhook", "", "URL to POST a JSON payload to on loss-start/loss-end")
+	notifySMTPAddrFlag := flag.String("notify-smtp-addr", "", "SMTP server address (host:port) for email notifications")
+	notifySMTPFromFlag := flag.String("notify-smtp-from", "", "From address for email notifications")
+	notifySMTPToFlag := flag.String("notify-smtp-to", "", "comma-separated To addresses for email notifications")
+	notifySMTPUserFlag := flag.String("notify-smtp-user", "", "SMTP auth username, if the relay requires it")
+	notifySMTPPasswordFlag := flag.String("notify-smtp-password", "", "SMTP auth password, if the relay requires it")
+	notifyExecFlag := flag.String("notify-exec", "", "shell command to run on loss-start/loss-end, with PLR_* env vars set")
+	notifyDebounceFlag := flag.Duration("notify-debounce", 0, "minimum loss duration before a loss-start notification is sent")
+	notifyCooldownFlag := flag.Duration("notify-cooldown", 0, "minimum time between notifications for the same host")
+	probeTimeoutFlag := flag.Duration("probe-timeout", DefaultProbeTimeout, "how long a single probe may go unanswered before it's considered lost")
+	pingIntervalFlag := flag.Duration("ping-interval", 0, "if set, overrides the pinger's default interval between probes")
+	flag.Parse()
+
+	var smtpTo []string
+	for _, addr := range strings.Split(*notifySMTPToFlag, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			smtpTo = append(smtpTo, addr)
+		}
+	}
+
+	opts := &Options{
+		HTTPAddr:           *httpAddrFlag,
+		LogFile:            *logFileFlag,
+		NotifyWebhook:      *notifyWebhookFlag,
+		NotifySMTPAddr:     *notifySMTPAddrFlag,
+		NotifySMTPFrom:     *notifySMTPFromFlag,
+		NotifySMTPTo:       smtpTo,
+		NotifySMTPUser:     *notifySMTPUserFlag,
+		NotifySMTPPassword: *notifySMTPPasswordFlag,
+		NotifyExec:         *notifyExecFlag,
+		NotifyDebounce:     *notifyDebounceFlag,
+		NotifyCooldown:     *notifyCooldownFlag,
+		ProbeTimeout:       *probeTimeoutFlag,
+		PingInterval:       *pingIntervalFlag,
+	}
+
+	if *configFlag != "" {
+		cfg, err := LoadConfig(*configFlag)
+		if err != nil {
+			return nil, err
+		}
+		if len(cfg.Hosts) == 0 {
+			return nil, fmt.Errorf("config %q declares no hosts", *configFlag)
+		}
+		opts.Hosts = cfg.Hosts
+		return opts, nil
+	}
+
+	if *hostsFlag != "" {
+		var hosts []string
+		for _, h := range strings.Split(*hostsFlag, ",") {
+			h = strings.TrimSpace(h)
+			if h != "" {
+				hosts = append(hosts, h)
+			}
+		}
+		if len(hosts) == 0 {
+			return nil, fmt.Errorf("-hosts was set but contained no usable entries")
+		}
+		opts.Hosts = hosts
+		return opts, nil
+	}
+
+	opts.Hosts = []string{defaultHost}
+	return opts, nil
+}
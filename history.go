@@ -0,0 +1,216 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// historySeconds is the size of the per-second ring buffer (one hour).
+	historySeconds = 3600
+	// historyMinutes is the size of the per-minute ring buffer (one week).
+	historyMinutes = 10080
+)
+
+// secondSample accumulates the RTTs recorded during a single unix second.
+type secondSample struct {
+	sec    int64
+	sum    time.Duration
+	count  int // successful probes
+	probes int // total probes, successful or not
+}
+
+// minuteSample is the rollup of the 60 secondSamples belonging to a minute.
+type minuteSample struct {
+	minute int64
+	avg    time.Duration
+	probes int
+	losses int
+}
+
+// History stores per-second and per-minute RTT samples for a single host in
+// fixed-size ring buffers, so the TUI and HTTP endpoint can render recent
+// trends without retaining an unbounded log of every probe.
+type History struct {
+	mu               sync.Mutex
+	seconds          [historySeconds]secondSample
+	minutes          [historyMinutes]minuteSample
+	lastRolledMinute int64
+}
+
+// NewHistory creates an empty History.
+func NewHistory() *History {
+	return &History{}
+}
+
+// Record stores the outcome of probing sequence number seq. rtt is ignored
+// when ok is false. It also rolls up any minutes that have completed since
+// the last call.
+func (h *History) Record(seq int, rtt time.Duration, ok bool) {
+	now := time.Now()
+	sec := now.Unix()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	slot := &h.seconds[secondIndex(sec)]
+	if slot.sec != sec {
+		*slot = secondSample{sec: sec}
+	}
+	slot.probes++
+	if ok {
+		slot.sum += rtt
+		slot.count++
+	}
+
+	h.rollupLocked(sec / 60)
+}
+
+func secondIndex(sec int64) int {
+	return int(((sec % historySeconds) + historySeconds) % historySeconds)
+}
+
+func minuteIndex(minute int64) int {
+	return int(((minute % historyMinutes) + historyMinutes) % historyMinutes)
+}
+
+// rollupLocked aggregates every minute that has completed since the last
+// recorded sample into the minute ring. h.mu must already be held.
+func (h *History) rollupLocked(currentMinute int64) {
+	if h.lastRolledMinute == 0 {
+		h.lastRolledMinute = currentMinute
+		return
+	}
+	for m := h.lastRolledMinute; m < currentMinute; m++ {
+		h.rollMinuteLocked(m)
+	}
+	h.lastRolledMinute = currentMinute
+}
+
+// rollMinuteLocked sums the 60 second-slots belonging to minute and stores
+// the average RTT, dividing by the count of successful probes.
+func (h *History) rollMinuteLocked(minute int64) {
+	start := minute * 60
+	var sum time.Duration
+	var count, probes int
+	for s := start; s < start+60; s++ {
+		slot := &h.seconds[secondIndex(s)]
+		if slot.sec == s {
+			sum += slot.sum
+			count += slot.count
+			probes += slot.probes
+		}
+	}
+
+	var avg time.Duration
+	if count > 0 {
+		avg = sum / time.Duration(count)
+	}
+	h.minutes[minuteIndex(minute)] = minuteSample{
+		minute: minute,
+		avg:    avg,
+		probes: probes,
+		losses: probes - count,
+	}
+}
+
+// SecondPoint is one second's worth of aggregated RTT data.
+type SecondPoint struct {
+	Time   time.Time
+	AvgRTT time.Duration
+	Loss   bool
+}
+
+// MinutePoint is one minute's worth of aggregated RTT data.
+type MinutePoint struct {
+	Time      time.Time
+	AvgRTT    time.Duration
+	LossCount int
+}
+
+// Snapshot is a point-in-time read of a History's ring buffers, ordered
+// oldest to newest.
+type Snapshot struct {
+	Seconds []SecondPoint
+	Minutes []MinutePoint
+}
+
+// Snapshot returns the populated contents of both ring buffers.
+func (h *History) Snapshot() Snapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now().Unix()
+	var snap Snapshot
+	for i := historySeconds - 1; i >= 0; i-- {
+		sec := now - int64(i)
+		slot := &h.seconds[secondIndex(sec)]
+		if slot.sec != sec {
+			continue
+		}
+		var avg time.Duration
+		if slot.count > 0 {
+			avg = slot.sum / time.Duration(slot.count)
+		}
+		snap.Seconds = append(snap.Seconds, SecondPoint{
+			Time:   time.Unix(sec, 0),
+			AvgRTT: avg,
+			Loss:   slot.probes > slot.count,
+		})
+	}
+
+	nowMinute := now / 60
+	for i := historyMinutes - 1; i >= 0; i-- {
+		minute := nowMinute - int64(i)
+		slot := &h.minutes[minuteIndex(minute)]
+		if slot.minute != minute {
+			continue
+		}
+		snap.Minutes = append(snap.Minutes, MinutePoint{
+			Time:      time.Unix(minute*60, 0),
+			AvgRTT:    slot.avg,
+			LossCount: slot.losses,
+		})
+	}
+
+	return snap
+}
+
+// sparkBlocks are the block characters used to render RTT magnitude,
+// cheapest (shortest RTT) to priciest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders the last n seconds of history as a single-line ASCII
+// sparkline, with lossy seconds marked in red using tview color tags.
+func (h *History) Sparkline(n int) string {
+	snap := h.Snapshot()
+	points := snap.Seconds
+	if len(points) > n {
+		points = points[len(points)-n:]
+	}
+	if len(points) == 0 {
+		return ""
+	}
+
+	var maxRTT time.Duration
+	for _, p := range points {
+		if p.AvgRTT > maxRTT {
+			maxRTT = p.AvgRTT
+		}
+	}
+
+	var b strings.Builder
+	for _, p := range points {
+		if p.Loss {
+			b.WriteString("[red]x[white]")
+			continue
+		}
+		idx := 0
+		if maxRTT > 0 {
+			idx = int(float64(p.AvgRTT) / float64(maxRTT) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[idx])
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSecondIndex(t *testing.T) {
+	tests := []struct {
+		name string
+		sec  int64
+		want int
+	}{
+		{"zero", 0, 0},
+		{"within range", 42, 42},
+		{"exactly one wrap", historySeconds, 0},
+		{"just past one wrap", historySeconds + 5, 5},
+		{"negative wraps forward", -1, historySeconds - 1},
+		{"large negative wraps forward", -historySeconds - 3, historySeconds - 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := secondIndex(tc.sec); got != tc.want {
+				t.Errorf("secondIndex(%d) = %d, want %d", tc.sec, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMinuteIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		minute int64
+		want   int
+	}{
+		{"zero", 0, 0},
+		{"within range", 100, 100},
+		{"exactly one wrap", historyMinutes, 0},
+		{"just past one wrap", historyMinutes + 3, 3},
+		{"negative wraps forward", -1, historyMinutes - 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := minuteIndex(tc.minute); got != tc.want {
+				t.Errorf("minuteIndex(%d) = %d, want %d", tc.minute, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRollMinuteLocked(t *testing.T) {
+	h := NewHistory()
+	minute := int64(10)
+	start := minute * 60
+
+	// 60 seconds: half successful at 10ms, half lost.
+	for s := start; s < start+60; s++ {
+		idx := secondIndex(s)
+		ok := (s-start)%2 == 0
+		sample := secondSample{sec: s, probes: 1}
+		if ok {
+			sample.sum = 10 * time.Millisecond
+			sample.count = 1
+		}
+		h.seconds[idx] = sample
+	}
+
+	h.rollMinuteLocked(minute)
+
+	got := h.minutes[minuteIndex(minute)]
+	if got.minute != minute {
+		t.Fatalf("minute = %d, want %d", got.minute, minute)
+	}
+	if got.probes != 60 {
+		t.Errorf("probes = %d, want 60", got.probes)
+	}
+	if got.losses != 30 {
+		t.Errorf("losses = %d, want 30", got.losses)
+	}
+	if got.avg != 10*time.Millisecond {
+		t.Errorf("avg = %v, want 10ms", got.avg)
+	}
+}
+
+func TestRollMinuteLockedAllLost(t *testing.T) {
+	h := NewHistory()
+	minute := int64(3)
+	start := minute * 60
+
+	for s := start; s < start+60; s++ {
+		h.seconds[secondIndex(s)] = secondSample{sec: s, probes: 1}
+	}
+
+	h.rollMinuteLocked(minute)
+
+	got := h.minutes[minuteIndex(minute)]
+	if got.probes != 60 || got.losses != 60 {
+		t.Errorf("probes/losses = %d/%d, want 60/60", got.probes, got.losses)
+	}
+	if got.avg != 0 {
+		t.Errorf("avg = %v, want 0 when every probe was lost", got.avg)
+	}
+}
+
+func TestRollupLockedFillsEveryCompletedMinute(t *testing.T) {
+	h := NewHistory()
+
+	// First call just primes lastRolledMinute; it shouldn't roll anything.
+	h.rollupLocked(5)
+	if h.minutes[minuteIndex(5)].minute == 5 {
+		t.Fatal("first rollupLocked call should not roll the priming minute")
+	}
+
+	h.rollupLocked(8)
+	for m := int64(5); m < 8; m++ {
+		if h.minutes[minuteIndex(m)].minute != m {
+			t.Errorf("minute %d was not rolled up", m)
+		}
+	}
+	if h.lastRolledMinute != 8 {
+		t.Errorf("lastRolledMinute = %d, want 8", h.lastRolledMinute)
+	}
+}
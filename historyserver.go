@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	svgWidth  = 900
+	svgHeight = 200
+)
+
+// NewHistoryServer builds the optional HTTP server that exposes each host's
+// RTT history as an SVG plot and a Prometheus /metrics endpoint, so the
+// recorder can be scraped and graphed from outside the TUI.
+func NewHistoryServer(monitor *Monitor) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		hosts := monitor.Hosts()
+		sort.Strings(hosts)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintln(w, "<!doctype html><title>packetlossrecorder</title><body>")
+		for _, host := range hosts {
+			fmt.Fprintf(w, "<h3>%s</h3><img src=\"/history/%s.svg\">\n", host, host)
+		}
+		fmt.Fprintln(w, "</body>")
+	})
+	mux.HandleFunc("/history/", func(w http.ResponseWriter, r *http.Request) {
+		host := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/history/"), ".svg")
+		hs := monitor.Host(host)
+		if hs == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/svg+xml")
+		fmt.Fprint(w, renderHistorySVG(hs.history.Snapshot()))
+	})
+	return &http.Server{Handler: mux}
+}
+
+// renderHistorySVG draws the last hour of per-second RTT samples as a
+// polyline, with lossy seconds marked as red dots along the axis.
+func renderHistorySVG(snap Snapshot) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		svgWidth, svgHeight, svgWidth, svgHeight)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#111"/>`, svgWidth, svgHeight)
+
+	points := snap.Seconds
+	if len(points) < 2 {
+		b.WriteString(`<text x="10" y="20" fill="#888">not enough data yet</text></svg>`)
+		return b.String()
+	}
+
+	var maxRTT float64
+	for _, p := range points {
+		if ns := float64(p.AvgRTT); ns > maxRTT {
+			maxRTT = ns
+		}
+	}
+	if maxRTT == 0 {
+		maxRTT = 1
+	}
+
+	xStep := float64(svgWidth) / float64(len(points)-1)
+	plotHeight := float64(svgHeight) - 20
+
+	b.WriteString(`<polyline fill="none" stroke="#4af" stroke-width="2" points="`)
+	for i, p := range points {
+		x := float64(i) * xStep
+		y := plotHeight - (float64(p.AvgRTT)/maxRTT)*plotHeight
+		fmt.Fprintf(&b, "%.1f,%.1f ", x, y)
+	}
+	b.WriteString(`"/>`)
+
+	for i, p := range points {
+		if !p.Loss {
+			continue
+		}
+		x := float64(i) * xStep
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="#f44"/>`, x, plotHeight+10)
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LogEvent is a single structured record describing something that
+// happened to a monitored host.
+type LogEvent struct {
+	Time    time.Time     `json:"time"`
+	Host    string        `json:"host"`
+	Event   string        `json:"event"` // recv, dup, loss_start, loss_end
+	Seq     int           `json:"seq,omitempty"`
+	RTT     time.Duration `json:"rtt,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+// Logger is the single place UI writes funnel through: every event is
+// persisted as a JSON line to a rotating ring log on disk and rendered as a
+// colored line for the relevant tview panel, so nothing is lost when a
+// panel scrolls off even if the process later crashes.
+type Logger struct {
+	ring *RingLog
+}
+
+// NewLogger wraps ring, which may be nil to disable on-disk persistence.
+func NewLogger(ring *RingLog) *Logger {
+	return &Logger{ring: ring}
+}
+
+// Log timestamps ev, persists it to the ring log, and returns the formatted
+// line a tview panel should display for it.
+func (l *Logger) Log(ev LogEvent) string {
+	ev.Time = time.Now()
+
+	if l.ring != nil {
+		if data, err := json.Marshal(ev); err == nil {
+			if err := l.ring.Append(data); err != nil {
+				return fmt.Sprintf("%s: [red]failed to write ring log: %v[white]\n", ev.Time.Format(time.RFC3339), err)
+			}
+		}
+	}
+
+	return formatLogEvent(ev)
+}
+
+// formatLogEvent renders a LogEvent as the colored, human-readable line the
+// TUI has always shown, preserving the previous message wording per event
+// type.
+func formatLogEvent(ev LogEvent) string {
+	ts := ev.Time.Format(time.RFC3339)
+	switch ev.Event {
+	case "recv":
+		return fmt.Sprintf("%s: %s\n", ts, ev.Message)
+	case "dup":
+		return fmt.Sprintf("%s: Duplicate packet received: %s\n", ts, ev.Message)
+	case "sent_during_loss":
+		return fmt.Sprintf("%s: [red]Packet sent:[white] %s\n", ts, ev.Message)
+	case "loss_start":
+		return fmt.Sprintf("%s: [red]%s: Packet Loss Detected![white]\n", ts, ev.Host)
+	case "loss_end":
+		return fmt.Sprintf("%s: [green]%s ended up losing %s.[white]\n", ts, ev.Host, ev.Message)
+	case "ping_error":
+		return fmt.Sprintf("%s: [red]Ping error: %s[white]\n", ts, ev.Message)
+	default:
+		return fmt.Sprintf("%s: %s\n", ts, ev.Message)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// LossEpisode records the exact span of a packet loss episode: the first
+// and last sequence numbers that timed out, how many did, and when it
+// started, so operators get precise data instead of an approximate counter.
+type LossEpisode struct {
+	StartSeq int
+	EndSeq   int
+	Count    int
+	Start    time.Time
+}
+
+// DefaultProbeTimeout is how long a probe is allowed to go unanswered
+// before the timeout wheel considers it lost, when -probe-timeout isn't
+// set.
+const DefaultProbeTimeout = 3 * time.Second
+
+// runLossDetector pops expired deadlines from hs's timeout wheel as they
+// occur and marks those exact sequences lost. It replaces the old
+// time.Sleep(2s) busy loop: instead of polling, it sleeps until the
+// soonest scheduled deadline (or is woken early by a new, sooner one).
+func (m *Monitor) runLossDetector(hs *HostState) {
+	for {
+		var wait time.Duration
+		if deadline, ok := hs.wheel.Next(); ok {
+			wait = time.Until(deadline)
+			if wait < 0 {
+				wait = 0
+			}
+		} else {
+			wait = time.Hour // idle; Schedule() will wake us sooner
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+			for _, seq := range hs.wheel.PopExpired(time.Now()) {
+				m.handleTimeout(hs, seq)
+			}
+		case <-hs.wheel.wake:
+			timer.Stop()
+		case <-hs.done:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// handleTimeout marks seq lost for hs, opening a new loss episode if one
+// isn't already active or extending the current one if it is.
+func (m *Monitor) handleTimeout(hs *HostState, seq int) {
+	hs.history.Record(seq, 0, false)
+
+	hs.mu.Lock()
+	isNewEpisode := hs.lossEpisode == nil
+	if isNewEpisode {
+		hs.lossEpisode = &LossEpisode{StartSeq: seq, EndSeq: seq, Count: 1, Start: time.Now()}
+	} else {
+		hs.lossEpisode.EndSeq = seq
+		hs.lossEpisode.Count++
+	}
+	hs.mu.Unlock()
+
+	if isNewEpisode {
+		m.logEvent(hs, LogEvent{Event: "loss_start", Seq: seq})
+		recordLossStart(hs.host)
+		if m.alerts != nil {
+			m.alerts.LossStart(hs.host)
+		}
+	}
+}
+
+// closeLossEpisode ends hs's active loss episode, if any, because seq
+// (newer than the episode's last lost sequence) just arrived successfully.
+// It returns the closed episode, or nil if hs wasn't in one.
+func (hs *HostState) closeLossEpisode(seq int) *LossEpisode {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if hs.lossEpisode == nil || seq <= hs.lossEpisode.EndSeq {
+		return nil
+	}
+	episode := hs.lossEpisode
+	hs.lossEpisode = nil
+	return episode
+}
+
+// inLossEpisode reports whether hs currently has an active loss episode.
+func (hs *HostState) inLossEpisode() bool {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.lossEpisode != nil
+}
+
+// describeLossEpisode summarizes an episode for the packet-loss panel.
+func describeLossEpisode(e *LossEpisode, duration time.Duration) string {
+	return fmt.Sprintf("%d packets (seq %d-%d) over %s", e.Count, e.StartSeq, e.EndSeq, duration)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	ping "github.com/prometheus-community/pro-bing"
+)
+
+// Prometheus metrics, one series per monitored host, registered with the
+// default registry so promhttp.Handler picks them up automatically.
+var (
+	metricPacketsSent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "packetlossrecorder_packets_sent",
+		Help: "Total ICMP echo requests sent to a target.",
+	}, []string{"host"})
+
+	metricPacketsReceived = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "packetlossrecorder_packets_received",
+		Help: "Total ICMP echo replies received from a target.",
+	}, []string{"host"})
+
+	metricPacketsDuplicated = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "packetlossrecorder_packets_duplicated",
+		Help: "Total duplicate ICMP echo replies received from a target.",
+	}, []string{"host"})
+
+	metricLossState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "packetlossrecorder_loss_state",
+		Help: "Whether a target is currently considered in a packet loss episode (1) or not (0).",
+	}, []string{"host"})
+
+	metricLossEpisodesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "packetlossrecorder_loss_episodes_total",
+		Help: "Number of packet loss episodes detected for a target.",
+	}, []string{"host"})
+
+	metricLossEpisodeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "packetlossrecorder_loss_episode_duration_seconds",
+		Help:    "Duration of completed packet loss episodes.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"host"})
+
+	metricRTTMin = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "packetlossrecorder_rtt_min_seconds",
+		Help: "Minimum round-trip time observed for a target.",
+	}, []string{"host"})
+
+	metricRTTAvg = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "packetlossrecorder_rtt_avg_seconds",
+		Help: "Average round-trip time observed for a target.",
+	}, []string{"host"})
+
+	metricRTTMax = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "packetlossrecorder_rtt_max_seconds",
+		Help: "Maximum round-trip time observed for a target.",
+	}, []string{"host"})
+
+	metricRTTStdDev = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "packetlossrecorder_rtt_stddev_seconds",
+		Help: "Standard deviation of round-trip time observed for a target.",
+	}, []string{"host"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricPacketsSent,
+		metricPacketsReceived,
+		metricPacketsDuplicated,
+		metricLossState,
+		metricLossEpisodesTotal,
+		metricLossEpisodeDuration,
+		metricRTTMin,
+		metricRTTAvg,
+		metricRTTMax,
+		metricRTTStdDev,
+	)
+}
+
+// recordStatsMetrics updates the gauge metrics for host from its latest
+// ping.Statistics snapshot.
+func recordStatsMetrics(host string, stats *ping.Statistics) {
+	metricPacketsSent.WithLabelValues(host).Set(float64(stats.PacketsSent))
+	metricPacketsReceived.WithLabelValues(host).Set(float64(stats.PacketsRecv))
+	metricPacketsDuplicated.WithLabelValues(host).Set(float64(stats.PacketsRecvDuplicates))
+	metricRTTMin.WithLabelValues(host).Set(stats.MinRtt.Seconds())
+	metricRTTAvg.WithLabelValues(host).Set(stats.AvgRtt.Seconds())
+	metricRTTMax.WithLabelValues(host).Set(stats.MaxRtt.Seconds())
+	metricRTTStdDev.WithLabelValues(host).Set(stats.StdDevRtt.Seconds())
+}
+
+// recordLossStart marks host as currently lossy and counts a new episode.
+func recordLossStart(host string) {
+	metricLossState.WithLabelValues(host).Set(1)
+	metricLossEpisodesTotal.WithLabelValues(host).Inc()
+}
+
+// recordLossEnd marks host as recovered and observes the episode's duration.
+func recordLossEnd(host string, duration float64) {
+	metricLossState.WithLabelValues(host).Set(0)
+	metricLossEpisodeDuration.WithLabelValues(host).Observe(duration)
+}
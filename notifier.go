@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// NotifyEvent describes a single loss-start or loss-end transition for a
+// host, passed to every configured Notifier.
+type NotifyEvent struct {
+	Host        string        `json:"host"`
+	Transition  string        `json:"transition"` // "loss_start" or "loss_end"
+	Time        time.Time     `json:"time"`
+	Duration    time.Duration `json:"duration,omitempty"` // only set on loss_end
+	PacketsLost int           `json:"packets_lost,omitempty"`
+}
+
+// Notifier is implemented by anything that can be told about a loss
+// transition, so operators can wire the recorder into existing
+// monitoring/paging systems without watching the TUI.
+type Notifier interface {
+	Notify(ev NotifyEvent) error
+}
+
+// WebhookNotifier POSTs a NotifyEvent as JSON to a configured URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier returns a WebhookNotifier using http.DefaultClient.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: http.DefaultClient}
+}
+
+// Notify implements Notifier.
+func (w *WebhookNotifier) Notify(ev NotifyEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	resp, err := w.Client.Post(w.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("webhook request to %s failed: %w", w.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned status %d", w.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// SMTPNotifier emails a NotifyEvent through an SMTP relay.
+type SMTPNotifier struct {
+	Addr string // host:port
+	Auth smtp.Auth
+	From string
+	To   []string
+}
+
+// Notify implements Notifier.
+func (s *SMTPNotifier) Notify(ev NotifyEvent) error {
+	subject := fmt.Sprintf("packetlossrecorder: %s %s", ev.Host, ev.Transition)
+	body := fmt.Sprintf("Host: %s\nTransition: %s\nTime: %s\nDuration: %s\nPackets lost: %d\n",
+		ev.Host, ev.Transition, ev.Time.Format(time.RFC3339), ev.Duration, ev.PacketsLost)
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
+		s.From, strings.Join(s.To, ", "), subject, body)
+
+	if err := smtp.SendMail(s.Addr, s.Auth, s.From, s.To, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send notification email via %s: %w", s.Addr, err)
+	}
+	return nil
+}
+
+// ExecNotifier runs a shell command for each transition, passing the event
+// fields as environment variables so operators can hook in arbitrary
+// scripts (e.g. paging tools that don't speak webhooks or SMTP).
+type ExecNotifier struct {
+	Command string
+}
+
+// Notify implements Notifier.
+func (e *ExecNotifier) Notify(ev NotifyEvent) error {
+	cmd := exec.Command("/bin/sh", "-c", e.Command)
+	cmd.Env = append(os.Environ(),
+		"PLR_HOST="+ev.Host,
+		"PLR_TRANSITION="+ev.Transition,
+		"PLR_TIME="+ev.Time.Format(time.RFC3339),
+		fmt.Sprintf("PLR_DURATION_SECONDS=%.3f", ev.Duration.Seconds()),
+		fmt.Sprintf("PLR_PACKETS_LOST=%d", ev.PacketsLost),
+	)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify command %q failed: %w (output: %s)", e.Command, err, output)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -13,197 +14,337 @@ import (
 	ping "github.com/prometheus-community/pro-bing"
 )
 
-const pingHost = "google.com"
-
-type Pinger struct {
-	p               *ping.Pinger
-	a               *tview.Application
-	packetLossState bool
-	packetsLost     int
-	timeLastSuccess time.Time
-	statsBox        *tview.TextView
-	logBox          *tview.TextView
-	mutex           sync.Mutex
-	packetLossBox   *tview.TextView
-	lastLossTime    time.Time
+// HostState holds everything the monitor tracks for a single ping target,
+// including the tview panels that render its status.
+type HostState struct {
+	host          string
+	p             *ping.Pinger
+	statsBox      *tview.TextView
+	logBox        *tview.TextView
+	packetLossBox *tview.TextView
+	historyBox    *tview.TextView
+	history       *History
+
+	wheel    *timeoutWheel
+	timeout  time.Duration
+	done     chan struct{}
+	stopOnce sync.Once
+
+	mu          sync.Mutex
+	lossEpisode *LossEpisode
 }
 
-func NewPinger(target string, statsBox, logBox, packetLossBox *tview.TextView) (*Pinger, error) {
+// stop halts hs's pinger and closes its done channel, exactly once, so that
+// repeated Ctrl-C presses (signal.Notify can redeliver more than once while
+// shutdown is in progress) never double-close done.
+func (hs *HostState) stop() {
+	hs.stopOnce.Do(func() {
+		hs.p.Stop()
+		close(hs.done)
+	})
+}
+
+// Monitor owns a pinger per host and fans out loss detection and stats
+// updates across all of them.
+type Monitor struct {
+	a      *tview.Application
+	hosts  map[string]*HostState
+	mutex  sync.Mutex
+	logger *Logger
+	alerts *AlertManager
+}
+
+// NewMonitor creates a Monitor with no hosts registered yet. Every UI write
+// the monitor makes is routed through logger first. alerts may be nil to
+// disable loss-transition notifications.
+func NewMonitor(app *tview.Application, logger *Logger, alerts *AlertManager) *Monitor {
+	return &Monitor{
+		a:      app,
+		hosts:  make(map[string]*HostState),
+		logger: logger,
+		alerts: alerts,
+	}
+}
+
+// AddHost creates a pinger for target, wires its callbacks to tag log lines
+// with the host, and registers it with the monitor. timeout is how long a
+// probe may go unanswered before the timeout wheel considers it lost; if
+// interval is non-zero it overrides the pinger's default send interval.
+func (m *Monitor) AddHost(target string, statsBox, logBox, packetLossBox, historyBox *tview.TextView, timeout, interval time.Duration) (*HostState, error) {
 	pinger, err := ping.NewPinger(target)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create pinger: %w", err)
+		return nil, fmt.Errorf("failed to create pinger for %s: %w", target, err)
+	}
+	if interval > 0 {
+		pinger.Interval = interval
 	}
 
-	p := &Pinger{
-		p:               pinger,
-		packetLossState: false,
-		packetsLost:     0,
-		timeLastSuccess: time.Now(),
-		statsBox:        statsBox,
-		logBox:          logBox,
-		packetLossBox:   packetLossBox,
+	hs := &HostState{
+		host:          target,
+		p:             pinger,
+		statsBox:      statsBox,
+		logBox:        logBox,
+		packetLossBox: packetLossBox,
+		historyBox:    historyBox,
+		history:       NewHistory(),
+		wheel:         newTimeoutWheel(),
+		timeout:       timeout,
+		done:          make(chan struct{}),
 	}
 
 	// Windows requires this.
 	pinger.SetPrivileged(true)
 
-	pinger.OnRecv = p.handleRecv
+	pinger.OnRecv = func(pkt *ping.Packet) {
+		m.handleRecv(hs, pkt)
+	}
 	pinger.OnDuplicateRecv = func(pkt *ping.Packet) {
-		p.LogMessage(fmt.Sprintf("Duplicate packet received: %v\n", pkt))
+		m.logEvent(hs, LogEvent{Event: "dup", Seq: pkt.Seq, RTT: pkt.Rtt, Message: fmt.Sprintf("%v", pkt)})
 	}
-
 	pinger.OnSend = func(pkt *ping.Packet) {
-		if p.packetLossState {
-			p.LogMessage(fmt.Sprintf("[red]Packet sent:[white] %v\n", pkt))
+		hs.wheel.Schedule(pkt.Seq, time.Now().Add(hs.timeout))
+		if hs.inLossEpisode() {
+			m.logEvent(hs, LogEvent{Event: "sent_during_loss", Seq: pkt.Seq, Message: fmt.Sprintf("%v", pkt)})
 		}
 	}
 
-	return p, nil
+	m.mutex.Lock()
+	m.hosts[target] = hs
+	m.mutex.Unlock()
+
+	return hs, nil
 }
 
-func (p *Pinger) handleRecv(pkt *ping.Packet) {
-	rttStr := fmt.Sprintf("%v", pkt.Rtt) // Store RTT as string for logging
-	p.LogMessage(fmt.Sprintf("%d bytes from %s: icmp_seq=%d time=%s ttl=%d\n",
-		pkt.Nbytes, pkt.IPAddr, pkt.Seq, rttStr, pkt.TTL))
+// Hosts returns the names of every host registered with the monitor.
+func (m *Monitor) Hosts() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	hosts := make([]string, 0, len(m.hosts))
+	for host := range m.hosts {
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
 
-	if p.packetLossState {
-		currentLossText := p.packetLossBox.GetText(false) // Get current text
-		recoverMsg := fmt.Sprintf("%s%s: [green]Ended up losing %v packets.[white]\n", currentLossText, time.Now().Format(time.RFC3339), p.packetsLost)
-		p.packetLossBox.SetText(recoverMsg)
-		p.packetLossBox.ScrollToEnd()
+// Host returns the HostState for a registered host, or nil if it isn't
+// monitored.
+func (m *Monitor) Host(host string) *HostState {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
-		p.packetLossState = false
-		p.timeLastSuccess = time.Now()
-		p.packetsLost = 0
-	} else {
-		p.timeLastSuccess = time.Now()
+	return m.hosts[host]
+}
+
+func (m *Monitor) handleRecv(hs *HostState, pkt *ping.Packet) {
+	onTime := hs.wheel.Cancel(pkt.Seq)
+
+	rttStr := fmt.Sprintf("%v", pkt.Rtt) // Store RTT as string for logging
+	m.logEvent(hs, LogEvent{
+		Event: "recv",
+		Seq:   pkt.Seq,
+		RTT:   pkt.Rtt,
+		Message: fmt.Sprintf("%d bytes from %s: icmp_seq=%d time=%s ttl=%d",
+			pkt.Nbytes, pkt.IPAddr, pkt.Seq, rttStr, pkt.TTL),
+	})
+	// If the wheel had already expired this sequence, handleTimeout already
+	// recorded it lost; recording a late success here too would double-count
+	// the same probe in History and draw a bogus "recovered" point next to
+	// the loss marker that's already there.
+	if onTime {
+		hs.history.Record(pkt.Seq, pkt.Rtt, true)
+	}
+
+	if episode := hs.closeLossEpisode(pkt.Seq); episode != nil {
+		duration := time.Since(episode.Start)
+		m.logEvent(hs, LogEvent{Event: "loss_end", Message: describeLossEpisode(episode, duration)})
+		recordLossEnd(hs.host, duration.Seconds())
+		if m.alerts != nil {
+			m.alerts.LossEnd(hs.host, episode.Count, duration)
+		}
 	}
 }
 
-func (p *Pinger) Run() error {
-	err := p.p.Run()
-	if err != nil {
-		return fmt.Errorf("ping failed: %w", err)
+// Run starts the pinger for a single host. It blocks until the pinger stops
+// and is intended to be called in its own goroutine.
+func (hs *HostState) Run() error {
+	if err := hs.p.Run(); err != nil {
+		return fmt.Errorf("ping failed for %s: %w", hs.host, err)
 	}
 	return nil
 }
 
-func (p *Pinger) CheckPacketLoss(app *tview.Application) {
-	time.Sleep(time.Second * 2)
+// UpdateStatsDisplay renders the latest RTT statistics for a single host,
+// including its standard deviation in microseconds.
+func (m *Monitor) UpdateStatsDisplay(hs *HostState, stats *ping.Statistics) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
-	if time.Since(p.timeLastSuccess) > time.Second*3 {
-		if !p.packetLossState {
-			p.packetLossState = true
-			p.lastLossTime = time.Now() // Store the loss time
+	statsText := fmt.Sprintf("%s\nTransmitted: %d\nReceived: %d\nPacket Loss: %v%%\nMin RTT: %v\nAvg RTT: %v\nMax RTT: %v\nStdDev RTT: %v\n",
+		hs.p.IPAddr(), stats.PacketsSent, stats.PacketsRecv, stats.PacketLoss, stats.MinRtt, stats.AvgRtt, stats.MaxRtt, stats.StdDevRtt.Microseconds())
+	hs.statsBox.SetText(statsText)
 
-			// Append to packetLossBox instead of overwriting
-			currentLossText := p.packetLossBox.GetText(false) // Get current text
-			newLossText := fmt.Sprintf("%s%s: [red]Packet Loss Detected![white]\n", currentLossText, time.Now().Format(time.RFC3339))
-			p.packetLossBox.SetText(newLossText)
-			p.packetLossBox.ScrollToEnd() // Scroll to bottom
-		}
-		p.packetsLost++
-	} else if p.packetLossState { // Check for recovery
-		p.packetLossState = false
-		p.packetsLost = 0
-	}
-	app.Draw() // Redraw the UI
+	recordStatsMetrics(hs.host, stats)
 }
 
-func (p *Pinger) UpdateStatsDisplay(stats *ping.Statistics) {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
+// UpdateHistoryDisplay renders the last two minutes of a host's RTT history
+// as an ASCII sparkline, with lossy seconds marked in red.
+func (m *Monitor) UpdateHistoryDisplay(hs *HostState) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
-	statsText := fmt.Sprintf("%s\nTransmitted: %d\nReceived: %d\nPacket Loss: %v%%\nMin RTT: %v\nAvg RTT: %v\nMax RTT: %v\n",
-		p.p.IPAddr(), stats.PacketsSent, stats.PacketsRecv, stats.PacketLoss, stats.MinRtt, stats.AvgRtt, stats.MaxRtt)
-	p.statsBox.SetText(statsText)
+	hs.historyBox.SetText(hs.history.Sparkline(120))
 }
 
-func (p *Pinger) LogMessage(message string) {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
+// logEvent is the single path every UI write takes: it tags ev with the
+// host, hands it to the structured logger for persistence, and appends the
+// formatted line to whichever panel the event belongs in.
+func (m *Monitor) logEvent(hs *HostState, ev LogEvent) {
+	ev.Host = hs.host
+	line := m.logger.Log(ev)
+
+	box := hs.logBox
+	if ev.Event == "loss_start" || ev.Event == "loss_end" {
+		box = hs.packetLossBox
+	}
 
-	currentTime := time.Now().Format(time.RFC3339)
-	logText := p.logBox.GetText(false)
-	newLogText := fmt.Sprintf("%s%s: %s", logText, currentTime, message)
-	p.logBox.SetText(newLogText)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
-	// Scroll to the bottom after setting text
-	p.logBox.ScrollToEnd()
-	p.a.Draw()
+	box.SetText(box.GetText(false) + line)
+	box.ScrollToEnd()
+	m.a.Draw()
 }
 
-func main() {
-	app := tview.NewApplication()
-	statsBox := tview.NewTextView()
+// newHostPanel builds the tview boxes (stats, packet loss, history, log)
+// used to render a single host's row.
+func newHostPanel(host string) (statsBox, packetLossBox, logBox, historyBox *tview.TextView) {
+	statsBox = tview.NewTextView()
 	statsBox.SetDynamicColors(true)
 	statsBox.SetTextColor(tcell.ColorWhite)
-	statsBox.SetBorder(true).SetTitle("Ping Statistics")
+	statsBox.SetBorder(true).SetTitle(fmt.Sprintf("%s: Ping Statistics", host))
 
-	packetLossBox := tview.NewTextView()
+	packetLossBox = tview.NewTextView()
 	packetLossBox.SetBorder(true)
-	packetLossBox.SetTitle("Packet Loss Details")
+	packetLossBox.SetTitle(fmt.Sprintf("%s: Packet Loss Details", host))
 	packetLossBox.SetDynamicColors(true)
 	packetLossBox.SetTextColor(tcell.ColorWhite)
 	packetLossBox.SetScrollable(true)
 
-	logBox := tview.NewTextView()
+	logBox = tview.NewTextView()
 	logBox.SetDynamicColors(true)
 	logBox.SetTextColor(tcell.ColorWhite)
 	logBox.SetScrollable(true) // Make the log box scrollable
-	logBox.SetBorder(true).SetTitle("Ping Log")
+	logBox.SetBorder(true).SetTitle(fmt.Sprintf("%s: Ping Log", host))
+
+	historyBox = tview.NewTextView()
+	historyBox.SetDynamicColors(true)
+	historyBox.SetTextColor(tcell.ColorWhite)
+	historyBox.SetBorder(true).SetTitle(fmt.Sprintf("%s: RTT History", host))
 
-	pinger, err := NewPinger(pingHost, statsBox, logBox, packetLossBox)
+	return statsBox, packetLossBox, logBox, historyBox
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "tail" {
+		if err := runTail(os.Args[2:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	opts, err := ParseFlags()
 	if err != nil {
-		fmt.Println("Error creating pinger:", err)
+		fmt.Println("Error parsing flags:", err)
 		os.Exit(1)
 	}
 
-	pinger.a = app
+	ring, err := OpenRingLog(opts.LogFile, DefaultRingSlotCount, DefaultRingSlotSize)
+	if err != nil {
+		fmt.Println("Error opening ring log:", err)
+		os.Exit(1)
+	}
+	defer ring.Close()
+
+	alerts := NewAlertManager(opts.Notifiers(), opts.NotifyDebounce, opts.NotifyCooldown)
+
+	app := tview.NewApplication()
+	monitor := NewMonitor(app, NewLogger(ring), alerts)
+
+	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+
+	var hostStates []*HostState
+	for _, host := range opts.Hosts {
+		statsBox, packetLossBox, logBox, historyBox := newHostPanel(host)
+
+		hs, err := monitor.AddHost(host, statsBox, logBox, packetLossBox, historyBox, opts.ProbeTimeout, opts.PingInterval)
+		if err != nil {
+			fmt.Println("Error creating pinger:", err)
+			os.Exit(1)
+		}
+		hostStates = append(hostStates, hs)
+
+		// Flexbox for top half (statsBox and packetLossBox) of this host's row.
+		topFlex := tview.NewFlex().
+			SetDirection(tview.FlexColumn). // Horizontal layout
+			AddItem(statsBox, 0, 1, false).
+			AddItem(packetLossBox, 0, 1, false)
+
+		// Row for this host (topFlex, historyBox and logBox).
+		hostFlex := tview.NewFlex().
+			SetDirection(tview.FlexRow).      // Vertical layout
+			AddItem(topFlex, 0, 3, false).    // Top half
+			AddItem(historyBox, 3, 0, false). // Sparkline
+			AddItem(logBox, 0, 3, false)      // Bottom half
+		hostFlex.SetBorder(true).SetTitle(host)
+
+		mainFlex.AddItem(hostFlex, 0, 1, false)
+	}
+
+	if opts.HTTPAddr != "" {
+		srv := NewHistoryServer(monitor)
+		srv.Addr = opts.HTTPAddr
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Println("history HTTP server error:", err)
+			}
+		}()
+	}
 
 	// Listen for Ctrl-C.
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 	go func() {
-		for _ = range c {
-			pinger.p.Stop()
+		for range c {
+			for _, hs := range hostStates {
+				hs.stop()
+			}
 			app.Stop()
 		}
 	}()
 
-	// Flexbox for top half (statsBox and packetLossBox)
-	topFlex := tview.NewFlex().
-		SetDirection(tview.FlexColumn). // Horizontal layout
-		AddItem(statsBox, 0, 1, false).
-		AddItem(packetLossBox, 0, 1, false)
-
-	// Main Flexbox (topFlex and logBox)
-	mainFlex := tview.NewFlex().
-		SetDirection(tview.FlexRow).   // Vertical layout
-		AddItem(topFlex, 0, 1, false). // Top half
-		AddItem(logBox, 0, 1, false)   // Bottom half
-
 	go func() {
 		for range time.Tick(time.Second) {
-			stats := pinger.p.Statistics()
-			pinger.UpdateStatsDisplay(stats)
+			for _, hs := range hostStates {
+				monitor.UpdateStatsDisplay(hs, hs.p.Statistics())
+				monitor.UpdateHistoryDisplay(hs)
+			}
 			app.Draw()
 		}
 	}()
 
-	go func() {
-		for {
-			pinger.CheckPacketLoss(app)
-		}
-	}()
+	for _, hs := range hostStates {
+		hs := hs
+		go monitor.runLossDetector(hs) // Event-driven loss detection for this host
 
-	go func() { // Run the pinger in a separate goroutine
-		err := pinger.Run()
-		if err != nil {
-			pinger.LogMessage(fmt.Sprintf("[red]Ping error: %v[white]\n", err))
-			app.Draw() // Update the UI to show the error
-		}
-	}()
+		go func() { // Run the pinger in a separate goroutine
+			if err := hs.Run(); err != nil {
+				monitor.logEvent(hs, LogEvent{Event: "ping_error", Message: err.Error()})
+				app.Draw() // Update the UI to show the error
+			}
+		}()
+	}
 
 	if err := app.SetRoot(mainFlex, true).Run(); err != nil {
 		panic(err)
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ringMagic identifies a packetlossrecorder ring log file.
+var ringMagic = [4]byte{'P', 'L', 'R', '1'}
+
+// ringHeaderSize is the fixed size, in bytes, of the header written at the
+// start of a ring log file.
+const ringHeaderSize = 32
+
+// DefaultRingSlotSize and DefaultRingSlotCount describe the default shape of
+// a ring log: enough 1024-byte slots to hold roughly the last 16k events.
+const (
+	DefaultRingSlotSize  = 1024
+	DefaultRingSlotCount = 16384
+)
+
+// RingLog is a fixed-size circular buffer of records persisted to disk. A
+// header holds the write offset and a generation counter, followed by N
+// fixed-size slots; once full, the oldest slot is overwritten. This lets a
+// crashed TUI be post-mortemed without an unbounded log file on disk.
+type RingLog struct {
+	f          *os.File
+	mu         sync.Mutex
+	slotSize   uint32
+	slotCount  uint32
+	writeIdx   uint64
+	generation uint64
+}
+
+// OpenRingLog opens (or creates) a ring log at path. If the file already
+// exists with a compatible header it is resumed from its last write
+// position; otherwise it is initialized with slotCount slots of slotSize
+// bytes each.
+func OpenRingLog(path string, slotCount, slotSize int) (*RingLog, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ring log %q: %w", path, err)
+	}
+
+	r := &RingLog{f: f, slotSize: uint32(slotSize), slotCount: uint32(slotCount)}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat ring log %q: %w", path, err)
+	}
+
+	if info.Size() == int64(ringHeaderSize)+int64(slotCount)*int64(slotSize) {
+		if err := r.readHeader(); err == nil {
+			return r, nil
+		}
+		// Fall through and reinitialize if the header didn't parse.
+	}
+
+	if err := r.f.Truncate(int64(ringHeaderSize) + int64(slotCount)*int64(slotSize)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to size ring log %q: %w", path, err)
+	}
+	if err := r.writeHeader(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RingLog) readHeader() error {
+	buf := make([]byte, ringHeaderSize)
+	if _, err := r.f.ReadAt(buf, 0); err != nil {
+		return err
+	}
+	if !bytes.Equal(buf[:4], ringMagic[:]) {
+		return fmt.Errorf("ring log header magic mismatch")
+	}
+	slotSize := binary.BigEndian.Uint32(buf[4:8])
+	slotCount := binary.BigEndian.Uint32(buf[8:12])
+	if slotSize != r.slotSize || slotCount != r.slotCount {
+		return fmt.Errorf("ring log shape mismatch")
+	}
+	r.writeIdx = binary.BigEndian.Uint64(buf[12:20])
+	r.generation = binary.BigEndian.Uint64(buf[20:28])
+	return nil
+}
+
+func (r *RingLog) writeHeader() error {
+	buf := make([]byte, ringHeaderSize)
+	copy(buf[:4], ringMagic[:])
+	binary.BigEndian.PutUint32(buf[4:8], r.slotSize)
+	binary.BigEndian.PutUint32(buf[8:12], r.slotCount)
+	binary.BigEndian.PutUint64(buf[12:20], r.writeIdx)
+	binary.BigEndian.PutUint64(buf[20:28], r.generation)
+	_, err := r.f.WriteAt(buf, 0)
+	return err
+}
+
+func (r *RingLog) slotOffset(idx uint64) int64 {
+	return int64(ringHeaderSize) + int64(idx%uint64(r.slotCount))*int64(r.slotSize)
+}
+
+// Append writes data into the next slot, overwriting the oldest record once
+// the ring has filled. data must fit within slotSize-4 bytes; longer
+// records are truncated so that a single oversized entry never breaks the
+// ring's fixed layout.
+func (r *RingLog) Append(data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	maxPayload := int(r.slotSize) - 4
+	if len(data) > maxPayload {
+		data = data[:maxPayload]
+	}
+
+	slot := make([]byte, r.slotSize)
+	binary.BigEndian.PutUint32(slot[:4], uint32(len(data)))
+	copy(slot[4:], data)
+
+	if _, err := r.f.WriteAt(slot, r.slotOffset(r.writeIdx)); err != nil {
+		return fmt.Errorf("failed to write ring log slot: %w", err)
+	}
+
+	r.writeIdx++
+	if r.writeIdx%uint64(r.slotCount) == 0 {
+		r.generation++
+	}
+	return r.writeHeader()
+}
+
+// Close closes the underlying file.
+func (r *RingLog) Close() error {
+	return r.f.Close()
+}
+
+// ReadAll returns every populated record currently in the ring, oldest
+// first.
+func (r *RingLog) ReadAll() ([][]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.slotCount
+	start := uint64(0)
+	if r.writeIdx >= uint64(r.slotCount) {
+		start = r.writeIdx
+	} else {
+		count = uint32(r.writeIdx)
+	}
+
+	records := make([][]byte, 0, count)
+	buf := make([]byte, r.slotSize)
+	for i := uint32(0); i < count; i++ {
+		idx := start + uint64(i)
+		if _, err := r.f.ReadAt(buf, r.slotOffset(idx)); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read ring log slot: %w", err)
+		}
+		n := binary.BigEndian.Uint32(buf[:4])
+		if n == 0 || n > r.slotSize-4 {
+			continue
+		}
+		rec := make([]byte, n)
+		copy(rec, buf[4:4+n])
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// WriteIndex returns the number of records written so far, for callers that
+// want to detect new writes without re-reading the whole ring.
+func (r *RingLog) WriteIndex() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.writeIdx
+}
+
+// ReadFrom returns every record written at or after writeIdx, oldest first,
+// clamped to what the ring still retains.
+func (r *RingLog) ReadFrom(writeIdx uint64) ([][]byte, uint64, error) {
+	r.mu.Lock()
+	current := r.writeIdx
+	slotCount := uint64(r.slotCount)
+	r.mu.Unlock()
+
+	if current <= writeIdx {
+		return nil, current, nil
+	}
+	if current-writeIdx > slotCount {
+		writeIdx = current - slotCount
+	}
+
+	records := make([][]byte, 0, current-writeIdx)
+	buf := make([]byte, r.slotSize)
+	for idx := writeIdx; idx < current; idx++ {
+		if _, err := r.f.ReadAt(buf, r.slotOffset(idx)); err != nil && err != io.EOF {
+			return nil, current, fmt.Errorf("failed to read ring log slot: %w", err)
+		}
+		n := binary.BigEndian.Uint32(buf[:4])
+		if n == 0 || n > r.slotSize-4 {
+			continue
+		}
+		rec := make([]byte, n)
+		copy(rec, buf[4:4+n])
+		records = append(records, rec)
+	}
+	return records, current, nil
+}
+
+// RefreshWriteIndex re-reads the header from disk, for a reader process
+// (e.g. the tail subcommand) polling a ring log another process is writing.
+func (r *RingLog) RefreshWriteIndex() (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.readHeader(); err != nil {
+		return 0, err
+	}
+	return r.writeIdx, nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestRingLogAppendReadAllWraps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.ring")
+	r, err := OpenRingLog(path, 4, 64)
+	if err != nil {
+		t.Fatalf("OpenRingLog: %v", err)
+	}
+	defer r.Close()
+
+	// Write 6 records into a 4-slot ring: the first two are overwritten,
+	// leaving records 2-5 behind, oldest first.
+	for i := 0; i < 6; i++ {
+		if err := r.Append([]byte{byte('0' + i)}); err != nil {
+			t.Fatalf("Append(%d): %v", i, err)
+		}
+	}
+
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	want := [][]byte{{'2'}, {'3'}, {'4'}, {'5'}}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(records[i], want[i]) {
+			t.Errorf("record[%d] = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestRingLogReadFromClampsToRetainedWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.ring")
+	r, err := OpenRingLog(path, 4, 64)
+	if err != nil {
+		t.Fatalf("OpenRingLog: %v", err)
+	}
+	defer r.Close()
+
+	for i := 0; i < 6; i++ {
+		if err := r.Append([]byte{byte('0' + i)}); err != nil {
+			t.Fatalf("Append(%d): %v", i, err)
+		}
+	}
+
+	// Asking from writeIdx 0 (long since overwritten) should clamp to
+	// whatever the ring still retains, not return stale/garbage slots.
+	records, current, err := r.ReadFrom(0)
+	if err != nil {
+		t.Fatalf("ReadFrom(0): %v", err)
+	}
+	if current != 6 {
+		t.Errorf("current = %d, want 6", current)
+	}
+	want := [][]byte{{'2'}, {'3'}, {'4'}, {'5'}}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(records[i], want[i]) {
+			t.Errorf("record[%d] = %q, want %q", i, records[i], want[i])
+		}
+	}
+
+	// Asking from the current write index should return nothing new.
+	records, _, err = r.ReadFrom(6)
+	if err != nil {
+		t.Fatalf("ReadFrom(6): %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("ReadFrom(current) returned %d records, want 0", len(records))
+	}
+
+	// Asking from partway through the retained window should return only
+	// what's newer than that point.
+	records, _, err = r.ReadFrom(4)
+	if err != nil {
+		t.Fatalf("ReadFrom(4): %v", err)
+	}
+	want = [][]byte{{'4'}, {'5'}}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(records[i], want[i]) {
+			t.Errorf("record[%d] = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestRingLogResumesAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.ring")
+	r, err := OpenRingLog(path, 4, 64)
+	if err != nil {
+		t.Fatalf("OpenRingLog: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := r.Append([]byte{byte('a' + i)}); err != nil {
+			t.Fatalf("Append(%d): %v", i, err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r2, err := OpenRingLog(path, 4, 64)
+	if err != nil {
+		t.Fatalf("reopen OpenRingLog: %v", err)
+	}
+	defer r2.Close()
+
+	if got := r2.WriteIndex(); got != 3 {
+		t.Fatalf("WriteIndex after reopen = %d, want 3", got)
+	}
+
+	records, err := r2.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll after reopen: %v", err)
+	}
+	want := [][]byte{{'a'}, {'b'}, {'c'}}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(records[i], want[i]) {
+			t.Errorf("record[%d] = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestRingLogAppendTruncatesOversizedPayload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.ring")
+	r, err := OpenRingLog(path, 2, 16)
+	if err != nil {
+		t.Fatalf("OpenRingLog: %v", err)
+	}
+	defer r.Close()
+
+	payload := bytes.Repeat([]byte("x"), 64)
+	if err := r.Append(payload); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if len(records[0]) != 16-4 {
+		t.Errorf("truncated record length = %d, want %d", len(records[0]), 16-4)
+	}
+}
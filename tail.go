@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// runTail implements the `packetlossrecorder tail` subcommand: it opens the
+// ring log, prints every record currently in it, then polls for and prints
+// new records as they're written, so a loss event can be post-mortemed even
+// if the TUI that recorded it has since crashed.
+func runTail(args []string) error {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	logFile := fs.String("log-file", defaultLogFile, "path to the ring log to tail")
+	follow := fs.Bool("f", true, "keep polling for new records after printing existing ones")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ring, err := OpenRingLog(*logFile, DefaultRingSlotCount, DefaultRingSlotSize)
+	if err != nil {
+		return err
+	}
+	defer ring.Close()
+
+	records, err := ring.ReadAll()
+	if err != nil {
+		return err
+	}
+	writeIdx := ring.WriteIndex()
+	for _, rec := range records {
+		printLogRecord(rec)
+	}
+
+	if !*follow {
+		return nil
+	}
+
+	for {
+		time.Sleep(500 * time.Millisecond)
+		current, err := ring.RefreshWriteIndex()
+		if err != nil {
+			return err
+		}
+		if current == writeIdx {
+			continue
+		}
+		records, writeIdx, err = ring.ReadFrom(writeIdx)
+		if err != nil {
+			return err
+		}
+		for _, rec := range records {
+			printLogRecord(rec)
+		}
+	}
+}
+
+func printLogRecord(rec []byte) {
+	var ev LogEvent
+	if err := json.Unmarshal(rec, &ev); err != nil {
+		fmt.Printf("malformed record: %s\n", rec)
+		return
+	}
+	fmt.Print(formatLogEvent(ev))
+}
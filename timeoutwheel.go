@@ -0,0 +1,117 @@
+package main
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// probeDeadline is a single scheduled timeout for an in-flight probe
+// sequence number, ordered by when it expires.
+type probeDeadline struct {
+	seq      int
+	deadline time.Time
+	index    int
+}
+
+// probeHeap is a container/heap min-heap of probeDeadline ordered by
+// deadline, so the soonest-to-expire probe is always at the root.
+type probeHeap []*probeDeadline
+
+func (h probeHeap) Len() int           { return len(h) }
+func (h probeHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+func (h probeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i]; h[i].index = i; h[j].index = j }
+func (h *probeHeap) Push(x interface{}) {
+	pd := x.(*probeDeadline)
+	pd.index = len(*h)
+	*h = append(*h, pd)
+}
+func (h *probeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	pd := old[n-1]
+	old[n-1] = nil
+	pd.index = -1
+	*h = old[:n-1]
+	return pd
+}
+
+// timeoutWheel tracks one deadline per in-flight probe sequence number. A
+// probe is scheduled when it's sent and canceled when its reply arrives; a
+// background reader pops whatever has expired to mark those exact
+// sequences lost.
+type timeoutWheel struct {
+	mu    sync.Mutex
+	heap  probeHeap
+	bySeq map[int]*probeDeadline
+	wake  chan struct{}
+}
+
+// newTimeoutWheel returns an empty timeoutWheel.
+func newTimeoutWheel() *timeoutWheel {
+	return &timeoutWheel{
+		bySeq: make(map[int]*probeDeadline),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// Schedule arranges for seq to expire at deadline unless canceled first.
+func (w *timeoutWheel) Schedule(seq int, deadline time.Time) {
+	w.mu.Lock()
+	pd := &probeDeadline{seq: seq, deadline: deadline}
+	heap.Push(&w.heap, pd)
+	w.bySeq[seq] = pd
+	w.mu.Unlock()
+
+	w.notify()
+}
+
+// Cancel removes seq's deadline if it hasn't already expired, reporting
+// whether it was still pending.
+func (w *timeoutWheel) Cancel(seq int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pd, ok := w.bySeq[seq]
+	if !ok {
+		return false
+	}
+	delete(w.bySeq, seq)
+	heap.Remove(&w.heap, pd.index)
+	return true
+}
+
+// Next returns the soonest pending deadline, if any.
+func (w *timeoutWheel) Next() (time.Time, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.heap) == 0 {
+		return time.Time{}, false
+	}
+	return w.heap[0].deadline, true
+}
+
+// PopExpired removes and returns every sequence number whose deadline is at
+// or before now.
+func (w *timeoutWheel) PopExpired(now time.Time) []int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var expired []int
+	for len(w.heap) > 0 && !w.heap[0].deadline.After(now) {
+		pd := heap.Pop(&w.heap).(*probeDeadline)
+		delete(w.bySeq, pd.seq)
+		expired = append(expired, pd.seq)
+	}
+	return expired
+}
+
+// notify wakes a blocked loss detector loop so it re-evaluates the new
+// soonest deadline instead of waiting on a stale timer.
+func (w *timeoutWheel) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
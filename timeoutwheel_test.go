@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeoutWheelPopExpiredOrdering(t *testing.T) {
+	w := newTimeoutWheel()
+	base := time.Now()
+
+	w.Schedule(1, base.Add(30*time.Millisecond))
+	w.Schedule(2, base.Add(10*time.Millisecond))
+	w.Schedule(3, base.Add(20*time.Millisecond))
+
+	// Nothing should be expired yet.
+	if expired := w.PopExpired(base); len(expired) != 0 {
+		t.Fatalf("PopExpired(base) = %v, want none expired", expired)
+	}
+
+	// Popping at the middle deadline should return only seq 2, oldest
+	// (soonest-to-expire) first.
+	expired := w.PopExpired(base.Add(15 * time.Millisecond))
+	if len(expired) != 1 || expired[0] != 2 {
+		t.Fatalf("PopExpired(+15ms) = %v, want [2]", expired)
+	}
+
+	// Popping well past every deadline should return the rest in deadline
+	// order: 3 before 1.
+	expired = w.PopExpired(base.Add(time.Hour))
+	if len(expired) != 2 || expired[0] != 3 || expired[1] != 1 {
+		t.Fatalf("PopExpired(+1h) = %v, want [3 1]", expired)
+	}
+
+	if _, ok := w.Next(); ok {
+		t.Fatal("expected wheel empty after all deadlines popped")
+	}
+}
+
+func TestTimeoutWheelCancel(t *testing.T) {
+	w := newTimeoutWheel()
+	base := time.Now()
+
+	w.Schedule(1, base.Add(10*time.Millisecond))
+	w.Schedule(2, base.Add(20*time.Millisecond))
+
+	if ok := w.Cancel(1); !ok {
+		t.Fatal("Cancel(1) = false, want true for a still-pending seq")
+	}
+	if ok := w.Cancel(1); ok {
+		t.Fatal("Cancel(1) = true on second call, want false: already canceled")
+	}
+	if ok := w.Cancel(99); ok {
+		t.Fatal("Cancel(99) = true, want false for a seq that was never scheduled")
+	}
+
+	deadline, ok := w.Next()
+	if !ok {
+		t.Fatal("Next() = false, want seq 2 still pending")
+	}
+	if !deadline.Equal(base.Add(20 * time.Millisecond)) {
+		t.Fatalf("Next() deadline = %v, want %v", deadline, base.Add(20*time.Millisecond))
+	}
+
+	expired := w.PopExpired(base.Add(time.Hour))
+	if len(expired) != 1 || expired[0] != 2 {
+		t.Fatalf("PopExpired after cancel = %v, want [2]", expired)
+	}
+}
+
+func TestTimeoutWheelNextEmpty(t *testing.T) {
+	w := newTimeoutWheel()
+	if _, ok := w.Next(); ok {
+		t.Fatal("Next() on empty wheel = true, want false")
+	}
+}